@@ -4,7 +4,10 @@ import(
 	"log"
 	"errors"
 	"image/color"
+	"unicode"
+	"unicode/utf8"
 	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
 	"github.com/hajimehoshi/ebiten/text"
 	"golang.org/x/image/font"
 	"github.com/golang/freetype/truetype"
@@ -27,54 +30,164 @@ func init() {
 	})
 }
 
+// ItemKind distinguishes a normal, selectable menu item from a separator or heading
+type ItemKind int
+
+const (
+	Regular   ItemKind = iota // a normal, selectable item
+	Separator                 // a thin dividing rule, never selectable
+	Heading                   // a group label, drawn like text but never selectable
+)
+
+// ItemType distinguishes a plain menu item from a checkable or radio-group one
+type ItemType int
+
+const (
+	Normal ItemType = iota // a plain item, activates and has no indicator
+	Check                  // toggles Checked independently of other items
+	Radio                  // Toggle sets Checked and clears Checked on every other Radio item in the same RadioGroup
+)
+
 // MenuItem represents an item in a menu list
 type MenuItem struct {
-	Name         string
-	Text         string
-	TxtX         int           // optional X location to draw text, if not provided x is 0
-	TxtY         int           // optional Y location to draw text, it not provided y is the menu list height - 5
-	image        *ebiten.Image // used to store the autogenerated image for the menu item
-	BgColour     *color.NRGBA  // optional background colour, overrides default colour
-	TxtColour    *color.NRGBA  // optional text colour, overrides default text colour
-	SelBgColour  *color.NRGBA  // optional selected background colour, overrides default selected colour
-	SelTxtColour *color.NRGBA  // optional selected text colour, overrides default selected text colour
+	Name              string
+	Text              string
+	TxtX              int           // optional X location to draw text, if not provided x is computed from Align, PadX and any indicator gutter
+	TxtY              int           // optional Y location to draw text, it not provided y is the menu list height - PadY - 5
+	image             *ebiten.Image // used to store the autogenerated image for the menu item
+	bevelActive       *ebiten.Image // pre-rendered bevel edges used while this item is selected
+	bevelPassive      *ebiten.Image // pre-rendered bevel edges used while this item is neither selected nor entered
+	bevelEntered      *ebiten.Image // pre-rendered bevel edges used while the cursor is over this item
+	BgColour          *color.NRGBA  // optional background colour, overrides default colour
+	TxtColour         *color.NRGBA  // optional text colour, overrides default text colour
+	SelBgColour       *color.NRGBA  // optional selected background colour, overrides default selected colour
+	SelTxtColour      *color.NRGBA  // optional selected text colour, overrides default selected text colour
+	SubMenu           *MenuList     // optional submenu to open when this item is activated, nil for a plain leaf item
+	Kind              ItemKind      // regular, separator or heading, defaults to Regular
+	Disabled          bool          // whether a Regular item is excluded from navigation and activation
+	DisabledTxtColour *color.NRGBA  // optional text colour used when Disabled is true, overrides default disabled colour
+	EnteredBgColour   *color.NRGBA  // optional background colour while the cursor is over the item, overrides default entered colour
+	EnteredTxtColour  *color.NRGBA  // optional text colour while the cursor is over the item, overrides default entered colour
+	ItemType          ItemType      // Normal, Check or Radio, defaults to Normal
+	Checked           bool          // whether a Check or Radio item is currently checked
+	RadioGroup        string        // items sharing a RadioGroup are mutually exclusive
+	IndicatorOn       *ebiten.Image // optional image drawn in the gutter when Checked, defaults to a generated square/circle
+	IndicatorOff      *ebiten.Image // optional image drawn in the gutter when not Checked, defaults to a blank square
+	Shortcut          *ebiten.Key   // optional key that activates this item directly, regardless of selection; nil means no shortcut
+	AccessChar        rune          // optional mnemonic letter in Text, activated with Alt held, underlined when Draw runs
+	Align             *Align        // optional, overrides the menu's default text alignment, nil inherits it
+	PadX              *int          // optional, overrides the menu's default horizontal text padding, nil inherits it
+	PadY              *int          // optional, overrides the menu's default vertical text padding, nil inherits it
 }
 
 
+// Align selects how an item's Text is positioned within its width
+type Align int
+
+const (
+	Left   Align = iota // text starts PadX pixels from the left edge, after the indicator gutter of a Check or Radio item
+	Center              // text is centred in the item's width, right of the indicator gutter of a Check or Radio item
+	Right               // text ends PadX pixels from the right edge
+)
+
+// Style selects how a MenuList's items are rendered
+type Style int
+
+const (
+	Flat    Style = iota // a plain colour fill, no bevel
+	Beveled              // a fill plus light/shadow edges for a 3D button look
+)
+
 // MenuList is a navigatable, selectable menu
 type MenuList struct {
-	Tx                  float64      // x translation of the menu
-	Ty                  float64      // y translation of the menu
-	Width               int          // width of all menu items
-	Height              int          // height of all menu items
-	Offx                float64      // x offset of subsequent menu items
-	Offy                float64      // y offset of subsequent menu items
-	DefaultBgColour     *color.NRGBA // default background colour
-	DefaultTxtColour    *color.NRGBA // default text colour
-	DefaultSelBgColour  *color.NRGBA // default selected background colour
-	DefaultSelTxtColour *color.NRGBA // default selected text colour
-	SelectedIndex       *int         // index of the item in list which is selected
-	MenuItems           []MenuItem   // menu items
+	Tx                       float64      // x translation of the menu
+	Ty                       float64      // y translation of the menu
+	Width                    int          // width of all menu items
+	Height                   int          // height of all menu items
+	Offx                     float64      // x offset of subsequent menu items
+	Offy                     float64      // y offset of subsequent menu items
+	DefaultBgColour          *color.NRGBA // default background colour
+	DefaultTxtColour         *color.NRGBA // default text colour
+	DefaultSelBgColour       *color.NRGBA // default selected background colour
+	DefaultSelTxtColour      *color.NRGBA // default selected text colour
+	DisabledDefaultTxtColour *color.NRGBA // default text colour for disabled items
+	DefaultEnteredBgColour   *color.NRGBA // default background colour while the cursor is over an item
+	DefaultEnteredTxtColour  *color.NRGBA // default text colour while the cursor is over an item
+	GroupDividerSize         int          // thickness in pixels of the rule drawn for a Separator item
+	Style                    Style        // Flat or Beveled, defaults to Flat
+	ActiveLevel              int          // bevel depth for the selected item, positive raised, negative sunken
+	PassiveLevel             int          // bevel depth for an item that is neither selected nor entered
+	EnteredLevel             int          // bevel depth for the item the cursor is over
+	ShortKeyInset            int          // right margin in pixels kept clear for an item's shortcut label
+	Align                    Align        // default text alignment, Left, Center or Right
+	PadX                     int          // default horizontal text padding
+	PadY                     int          // default vertical text padding
+	SelectedIndex            *int         // index of the item in list which is selected
+	EnteredIndex             *int         // index of the item the cursor is currently over, -1 if none
+	MenuItems                []MenuItem   // menu items
 }
 
 // MenuListInput is an object used to create a menu list
 type MenuListInput struct {
-	Tx                  float64      // optional, x translation of the menu, if not provided will be 0
-	Ty                  float64      // optional, y translation of the menu, if not provided will be 0
-	Width               int          // mandatory, width of all menu items
-	Height              int          // mandatory, height of all menu items
-	Offx                float64      // optional, offset of subsequent menu items, if not provided will 0
-	Offy                float64      // optional, offset of subsequent menu items, if not provided will be menu item height
-	DefaultBgColour     *color.NRGBA // optional, default background colour of menu, if not provided will be cyan
-	DefaultTxtColour    *color.NRGBA // optional, default text colour, if not provided will be black
-	DefaultSelBGColour  *color.NRGBA // optional, default selected background colour of menu, if not provided will be magenta
-	DefaultSelTxtColour *color.NRGBA //optional, default selected text colour of menu, if not provided it will be white
-	MenuItems           []MenuItem   // mandtory, list of menu items
+	Tx                       float64      // optional, x translation of the menu, if not provided will be 0
+	Ty                       float64      // optional, y translation of the menu, if not provided will be 0
+	Width                    int          // mandatory, width of all menu items
+	Height                   int          // mandatory, height of all menu items
+	Offx                     float64      // optional, offset of subsequent menu items, if not provided will 0
+	Offy                     float64      // optional, offset of subsequent menu items, if not provided will be menu item height
+	DefaultBgColour          *color.NRGBA // optional, default background colour of menu, if not provided will be cyan
+	DefaultTxtColour         *color.NRGBA // optional, default text colour, if not provided will be black
+	DefaultSelBGColour       *color.NRGBA // optional, default selected background colour of menu, if not provided will be magenta
+	DefaultSelTxtColour      *color.NRGBA //optional, default selected text colour of menu, if not provided it will be white
+	DisabledDefaultTxtColour *color.NRGBA // optional, default text colour for disabled items, if not provided will be grey
+	DefaultEnteredBgColour   *color.NRGBA // optional, default background colour while the cursor is over an item, if not provided will be DefaultSelBGColour
+	DefaultEnteredTxtColour  *color.NRGBA // optional, default text colour while the cursor is over an item, if not provided will be DefaultSelTxtColour
+	GroupDividerSize         int          // optional, thickness in pixels of a Separator's rule, if not provided will be 2
+	Style                    Style        // optional, Flat or Beveled, if not provided will be Flat
+	ActiveLevel              int          // optional, bevel depth for the selected item, positive raised, negative sunken
+	PassiveLevel             int          // optional, bevel depth for an item that is neither selected nor entered
+	EnteredLevel             int          // optional, bevel depth for the item the cursor is over
+	ShortKeyInset            int          // optional, right margin in pixels kept clear for a shortcut label, if not provided will be 8
+	Align                    Align        // optional, default text alignment, if not provided will be Left
+	PadX                     int          // optional, default horizontal text padding, if not provided will be 0
+	PadY                     int          // optional, default vertical text padding, if not provided will be 0
+	AutoWidth                bool         // optional, if true Width is replaced by the widest item's measured Text plus 2*PadX, plus the indicator gutter if any item is Check or Radio, plus ShortKeyInset and the widest shortcut label if any item has a Shortcut
+	MenuItems                []MenuItem   // mandtory, list of menu items
 }
 
 //NewMenu constructs a new menu from a MenuListInput
 func NewMenu(input MenuListInput) (MenuList, error) {
 
+	if input.ShortKeyInset == 0 {
+		input.ShortKeyInset = 8
+	}
+
+	if input.AutoWidth {
+		maxWidth := 0
+		maxShortcutWidth := 0
+		needsGutter := false
+		for _, item := range input.MenuItems {
+			if w := font.MeasureString(mplusNormalFont, item.Text).Ceil(); w > maxWidth {
+				maxWidth = w
+			}
+			if item.ItemType != Normal {
+				needsGutter = true
+			}
+			if item.Shortcut != nil {
+				if w := font.MeasureString(mplusNormalFont, item.Shortcut.String()).Ceil(); w > maxShortcutWidth {
+					maxShortcutWidth = w
+				}
+			}
+		}
+		input.Width = maxWidth + 2*input.PadX
+		if needsGutter {
+			input.Width += indicatorSize(input.Height) + 2*indicatorPad
+		}
+		if maxShortcutWidth > 0 {
+			input.Width += input.ShortKeyInset + maxShortcutWidth
+		}
+	}
+
 	if input.Width == 0 {
 		return MenuList{}, errors.New("Mandatory input field width is missing")
 	}
@@ -105,21 +218,57 @@ func NewMenu(input MenuListInput) (MenuList, error) {
 		input.DefaultSelTxtColour = &color.NRGBA{0xff, 0xff, 0xff, 0xff}
 	}
 
+	if input.DisabledDefaultTxtColour == nil {
+		input.DisabledDefaultTxtColour = &color.NRGBA{0x80, 0x80, 0x80, 0xff}
+	}
+
+	if input.DefaultEnteredBgColour == nil {
+		input.DefaultEnteredBgColour = input.DefaultSelBGColour
+	}
+
+	if input.DefaultEnteredTxtColour == nil {
+		input.DefaultEnteredTxtColour = input.DefaultSelTxtColour
+	}
+
+	if input.GroupDividerSize == 0 {
+		input.GroupDividerSize = 2
+	}
+
 	defaultSelectedIndex := 0
+	for i, item := range input.MenuItems {
+		if item.Kind == Regular && !item.Disabled {
+			defaultSelectedIndex = i
+			break
+		}
+	}
+	defaultEnteredIndex := -1
 
 	ml := MenuList{
-		Tx:                  input.Tx,
-		Ty:                  input.Ty,
-		Width:               input.Width,
-		Height:              input.Height,
-		Offx:                input.Offx,
-		Offy:                input.Offy,
-		DefaultBgColour:     input.DefaultBgColour,
-		DefaultTxtColour:    input.DefaultTxtColour,
-		DefaultSelBgColour:  input.DefaultSelBGColour,
-		DefaultSelTxtColour: input.DefaultSelTxtColour,
-		SelectedIndex:       &defaultSelectedIndex,
-		MenuItems:           input.MenuItems,
+		Tx:                       input.Tx,
+		Ty:                       input.Ty,
+		Width:                    input.Width,
+		Height:                   input.Height,
+		Offx:                     input.Offx,
+		Offy:                     input.Offy,
+		DefaultBgColour:          input.DefaultBgColour,
+		DefaultTxtColour:         input.DefaultTxtColour,
+		DefaultSelBgColour:       input.DefaultSelBGColour,
+		DefaultSelTxtColour:      input.DefaultSelTxtColour,
+		DisabledDefaultTxtColour: input.DisabledDefaultTxtColour,
+		DefaultEnteredBgColour:   input.DefaultEnteredBgColour,
+		DefaultEnteredTxtColour:  input.DefaultEnteredTxtColour,
+		GroupDividerSize:         input.GroupDividerSize,
+		Style:                    input.Style,
+		ActiveLevel:              input.ActiveLevel,
+		PassiveLevel:             input.PassiveLevel,
+		EnteredLevel:             input.EnteredLevel,
+		ShortKeyInset:            input.ShortKeyInset,
+		Align:                    input.Align,
+		PadX:                     input.PadX,
+		PadY:                     input.PadY,
+		SelectedIndex:            &defaultSelectedIndex,
+		EnteredIndex:             &defaultEnteredIndex,
+		MenuItems:                input.MenuItems,
 	}
 
 	// set override colours if needed otherwise use default colours
@@ -148,8 +297,85 @@ func NewMenu(input MenuListInput) (MenuList, error) {
 			ml.MenuItems[i].SelTxtColour = ml.DefaultSelTxtColour
 		}
 
+		if item.DisabledTxtColour != nil {
+			ml.MenuItems[i].DisabledTxtColour = item.DisabledTxtColour
+		} else {
+			ml.MenuItems[i].DisabledTxtColour = ml.DisabledDefaultTxtColour
+		}
+
+		if item.EnteredBgColour != nil {
+			ml.MenuItems[i].EnteredBgColour = item.EnteredBgColour
+		} else {
+			ml.MenuItems[i].EnteredBgColour = ml.DefaultEnteredBgColour
+		}
+
+		if item.EnteredTxtColour != nil {
+			ml.MenuItems[i].EnteredTxtColour = item.EnteredTxtColour
+		} else {
+			ml.MenuItems[i].EnteredTxtColour = ml.DefaultEnteredTxtColour
+		}
+
+		if ml.Style == Beveled && item.Kind != Separator {
+			ml.MenuItems[i].bevelActive = buildBevel(ml.Width, ml.Height, ml.MenuItems[i].SelBgColour, ml.ActiveLevel)
+			ml.MenuItems[i].bevelPassive = buildBevel(ml.Width, ml.Height, ml.MenuItems[i].BgColour, ml.PassiveLevel)
+			ml.MenuItems[i].bevelEntered = buildBevel(ml.Width, ml.Height, ml.MenuItems[i].EnteredBgColour, ml.EnteredLevel)
+		}
+
+		align := ml.Align
+		if item.Align != nil {
+			align = *item.Align
+		}
+		ml.MenuItems[i].Align = &align
+
+		padX := ml.PadX
+		if item.PadX != nil {
+			padX = *item.PadX
+		}
+		ml.MenuItems[i].PadX = &padX
+
+		padY := ml.PadY
+		if item.PadY != nil {
+			padY = *item.PadY
+		}
+		ml.MenuItems[i].PadY = &padY
+
+		gutter := 0
+		if item.ItemType != Normal {
+			gutter = indicatorSize(ml.Height) + 2*indicatorPad
+		}
+
+		if item.TxtX == 0 {
+			textWidth := font.MeasureString(mplusNormalFont, item.Text).Ceil()
+			switch align {
+			case Center:
+				ml.MenuItems[i].TxtX = gutter + (ml.Width-gutter-textWidth)/2
+			case Right:
+				ml.MenuItems[i].TxtX = ml.Width - textWidth - padX
+			default: // Left
+				ml.MenuItems[i].TxtX = gutter + padX
+			}
+		}
+
 		if item.TxtY == 0 {
-			ml.MenuItems[i].TxtY = ml.Height - 5  // default value for text y height
+			ml.MenuItems[i].TxtY = ml.Height - padY - 5 // default value for text y height
+		}
+
+		if item.ItemType != Normal {
+			size := indicatorSize(ml.Height)
+
+			if item.IndicatorOn != nil {
+				ml.MenuItems[i].IndicatorOn = item.IndicatorOn
+			} else if item.ItemType == Check {
+				ml.MenuItems[i].IndicatorOn = newFilledSquare(size, ml.MenuItems[i].TxtColour)
+			} else {
+				ml.MenuItems[i].IndicatorOn = newFilledCircle(size, ml.MenuItems[i].TxtColour)
+			}
+
+			if item.IndicatorOff != nil {
+				ml.MenuItems[i].IndicatorOff = item.IndicatorOff
+			} else {
+				ml.MenuItems[i].IndicatorOff, _ = ebiten.NewImage(size, size, ebiten.FilterNearest)
+			}
 		}
 
 	}
@@ -167,43 +393,415 @@ func (m *MenuList) GetSelectedItem() string {
 	return m.MenuItems[*m.SelectedIndex].Name
 }
 
-//IncrementSelected increments the selected index provided it is not already at maximum
+//Toggle updates the Checked state of the item at index: for a Check item it
+//flips Checked, for a Radio item it sets Checked=true and clears Checked on
+//every other item sharing the same RadioGroup. It does nothing for a Normal item.
+func (m *MenuList) Toggle(index int) {
+	item := &m.MenuItems[index]
+
+	switch item.ItemType {
+	case Check:
+		item.Checked = !item.Checked
+	case Radio:
+		for i := range m.MenuItems {
+			if m.MenuItems[i].ItemType == Radio && m.MenuItems[i].RadioGroup == item.RadioGroup {
+				m.MenuItems[i].Checked = false
+			}
+		}
+		item.Checked = true
+	}
+}
+
+//indicatorPad is the blank margin kept around a generated check/radio indicator
+const indicatorPad = 4
+
+//indicatorSize returns the side length of the check/radio indicator drawn in
+//a menu item of the given height
+func indicatorSize(height int) int {
+	return height - 2*indicatorPad
+}
+
+//newFilledSquare returns a size x size image filled with col, the default Check indicator
+func newFilledSquare(size int, col *color.NRGBA) *ebiten.Image {
+	img, _ := ebiten.NewImage(size, size, ebiten.FilterNearest)
+	img.Fill(col)
+	return img
+}
+
+//newFilledCircle returns a size x size image containing a filled circle of col, the default Radio indicator
+func newFilledCircle(size int, col *color.NRGBA) *ebiten.Image {
+	img, _ := ebiten.NewImage(size, size, ebiten.FilterNearest)
+
+	radius := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx := float64(x) + 0.5 - radius
+			dy := float64(y) + 0.5 - radius
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(x, y, col)
+			}
+		}
+	}
+	return img
+}
+
+//IncrementSelected moves the selected index down to the next selectable item, if any
 func (m *MenuList) IncrementSelected() {
 	maxIndex := len(m.MenuItems) - 1
-	if *m.SelectedIndex < maxIndex {
-		*m.SelectedIndex++
+	for i := *m.SelectedIndex + 1; i <= maxIndex; i++ {
+		if m.selectable(i) {
+			*m.SelectedIndex = i
+			return
+		}
 	}
 }
 
-//DecrementSelected decrements the selected index provided it is not already at minimum
+//DecrementSelected moves the selected index up to the previous selectable item, if any
 func (m *MenuList) DecrementSelected() {
-	minIndex := 0
-	if *m.SelectedIndex > minIndex {
-		*m.SelectedIndex--
+	for i := *m.SelectedIndex - 1; i >= 0; i-- {
+		if m.selectable(i) {
+			*m.SelectedIndex = i
+			return
+		}
 	}
 }
 
+//selectable reports whether the item at index can be navigated to and activated
+func (m *MenuList) selectable(index int) bool {
+	item := m.MenuItems[index]
+	return item.Kind == Regular && !item.Disabled
+}
+
 //Draw draws the menu to the screen
 func (m *MenuList) Draw(screen *ebiten.Image) {
+	m.draw(screen, 1)
+}
+
+//drawDimmed draws the menu at reduced opacity, used for parent menus left
+//on screen behind an open SubMenu
+func (m *MenuList) drawDimmed(screen *ebiten.Image) {
+	m.draw(screen, 0.4)
+}
+
+//draw renders each menu item, fading the whole menu to alpha (1 is fully opaque)
+func (m *MenuList) draw(screen *ebiten.Image, alpha float64) {
 
 	opts := &ebiten.DrawImageOptions{}
 	opts.GeoM.Translate(m.Tx, m.Ty)
+	opts.ColorM.Scale(1, 1, 1, alpha)
 
 	for index, item := range m.MenuItems {
 
-		if index == *m.SelectedIndex {
-			item.image.Fill(item.SelBgColour)
-		} else {
-			item.image.Fill(item.BgColour)
+		var bg *color.NRGBA
+		var bevel *ebiten.Image
+		switch {
+		case index == *m.SelectedIndex:
+			bg, bevel = item.SelBgColour, item.bevelActive
+		case index == *m.EnteredIndex:
+			bg, bevel = item.EnteredBgColour, item.bevelEntered
+		default:
+			bg, bevel = item.BgColour, item.bevelPassive
+		}
+		item.image.Fill(bg)
+
+		if m.Style == Beveled && item.Kind != Separator {
+			item.image.DrawImage(bevel, &ebiten.DrawImageOptions{})
+		}
+
+		if item.ItemType != Normal {
+			indicator := item.IndicatorOff
+			if item.Checked {
+				indicator = item.IndicatorOn
+			}
+			_, ih := indicator.Size()
+			indOpts := &ebiten.DrawImageOptions{}
+			indOpts.GeoM.Translate(float64(indicatorPad), float64((m.Height-ih)/2))
+			item.image.DrawImage(indicator, indOpts)
 		}
 
-		if index == *m.SelectedIndex {
-			text.Draw(item.image, item.Text, mplusNormalFont, item.TxtX, item.TxtY, item.SelTxtColour)
+		if item.Kind == Separator {
+			ruleY := (m.Height - m.GroupDividerSize) / 2
+			rule, _ := ebiten.NewImage(m.Width, m.GroupDividerSize, ebiten.FilterNearest)
+			rule.Fill(item.TxtColour)
+			ruleOpts := &ebiten.DrawImageOptions{}
+			ruleOpts.GeoM.Translate(0, float64(ruleY))
+			item.image.DrawImage(rule, ruleOpts)
 		} else {
-			text.Draw(item.image, item.Text, mplusNormalFont, item.TxtX, item.TxtY, item.TxtColour)
+			var txtColour *color.NRGBA
+			switch {
+			case index == *m.SelectedIndex:
+				txtColour = item.SelTxtColour
+			case item.Disabled && item.Kind == Regular:
+				txtColour = item.DisabledTxtColour
+			case index == *m.EnteredIndex:
+				txtColour = item.EnteredTxtColour
+			default:
+				txtColour = item.TxtColour
+			}
+
+			text.Draw(item.image, item.Text, mplusNormalFont, item.TxtX, item.TxtY, txtColour)
+			m.drawAccessUnderline(item.image, item, txtColour)
+
+			if item.Shortcut != nil {
+				m.drawShortcutLabel(item.image, item, txtColour)
+			}
 		}
 
 		screen.DrawImage(item.image, opts)
 		opts.GeoM.Translate(m.Offx, m.Offy)
 	}
 }
+
+//bevelShadeStep is the per-channel colour delta applied for each level of bevel depth
+const bevelShadeStep = 16
+
+//buildBevel renders a width x height overlay image holding level rows of a
+//lightened colour along the top/left and a darkened colour along the
+//bottom/right, for a raised look, transparent everywhere else so Draw can
+//composite it over an item's fill every frame without reallocating it. A
+//negative level sinks the button instead, swapping the light and dark edges.
+//Called at NewMenu time, once for each of an item's active, passive and
+//entered states, never from the per-frame Draw path.
+func buildBevel(width, height int, base *color.NRGBA, level int) *ebiten.Image {
+	overlay, _ := ebiten.NewImage(width, height, ebiten.FilterNearest)
+
+	if level == 0 {
+		return overlay
+	}
+
+	depth := level
+	if depth < 0 {
+		depth = -depth
+	}
+
+	light := shadeColour(base, depth*bevelShadeStep)
+	dark := shadeColour(base, -depth*bevelShadeStep)
+	if level < 0 {
+		light, dark = dark, light
+	}
+
+	for row := 0; row < depth; row++ {
+		hEdge, _ := ebiten.NewImage(width-row, 1, ebiten.FilterNearest)
+		vEdge, _ := ebiten.NewImage(1, height-row, ebiten.FilterNearest)
+
+		hEdge.Fill(light)
+		vEdge.Fill(light)
+		topOpts := &ebiten.DrawImageOptions{}
+		topOpts.GeoM.Translate(float64(row), float64(row))
+		overlay.DrawImage(hEdge, topOpts)
+		leftOpts := &ebiten.DrawImageOptions{}
+		leftOpts.GeoM.Translate(float64(row), float64(row))
+		overlay.DrawImage(vEdge, leftOpts)
+
+		hEdge.Fill(dark)
+		vEdge.Fill(dark)
+		bottomOpts := &ebiten.DrawImageOptions{}
+		bottomOpts.GeoM.Translate(float64(row), float64(height-1-row))
+		overlay.DrawImage(hEdge, bottomOpts)
+		rightOpts := &ebiten.DrawImageOptions{}
+		rightOpts.GeoM.Translate(float64(width-1-row), float64(row))
+		overlay.DrawImage(vEdge, rightOpts)
+	}
+	return overlay
+}
+
+//shadeColour returns base with delta added to each of its colour channels, clamped to [0, 255]
+func shadeColour(base *color.NRGBA, delta int) *color.NRGBA {
+	return &color.NRGBA{
+		R: clampChannel(base.R, delta),
+		G: clampChannel(base.G, delta),
+		B: clampChannel(base.B, delta),
+		A: base.A,
+	}
+}
+
+//clampChannel adds delta to c, clamping the result to a valid uint8 colour channel
+func clampChannel(c uint8, delta int) uint8 {
+	shaded := int(c) + delta
+	if shaded < 0 {
+		return 0
+	}
+	if shaded > 255 {
+		return 255
+	}
+	return uint8(shaded)
+}
+
+//hitTest returns the index of the menu item whose rectangle contains (x, y),
+//or -1 if none does
+func (m *MenuList) hitTest(x, y int) int {
+	for i := range m.MenuItems {
+		left := m.Tx + float64(i)*m.Offx
+		top := m.Ty + float64(i)*m.Offy
+		if float64(x) >= left && float64(x) < left+float64(m.Width) &&
+			float64(y) >= top && float64(y) < top+float64(m.Height) {
+			return i
+		}
+	}
+	return -1
+}
+
+//Update hit-tests the cursor against the menu, tracking EnteredIndex, and
+//returns the name of the entered/selected item and true when the user clicks
+//it or presses Enter
+func (m *MenuList) Update() (string, bool) {
+	x, y := ebiten.CursorPosition()
+	*m.EnteredIndex = -1
+	if hit := m.hitTest(x, y); hit >= 0 && m.selectable(hit) {
+		*m.EnteredIndex = hit
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && *m.EnteredIndex >= 0 {
+		*m.SelectedIndex = *m.EnteredIndex
+		return m.GetSelectedItem(), true
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && m.selectable(*m.SelectedIndex) {
+		return m.GetSelectedItem(), true
+	}
+
+	return "", false
+}
+
+//HandleInput checks each selectable item's Shortcut, and (while Alt is held)
+//its AccessChar, against the keys just pressed this frame. It returns the
+//matching item's name directly, skipping the current selection entirely.
+func (m *MenuList) HandleInput() (string, bool) {
+	altHeld := ebiten.IsKeyPressed(ebiten.KeyAlt)
+
+	for i, item := range m.MenuItems {
+		if !m.selectable(i) {
+			continue
+		}
+
+		if item.Shortcut != nil && inpututil.IsKeyJustPressed(*item.Shortcut) {
+			return item.Name, true
+		}
+
+		if altHeld && item.AccessChar != 0 {
+			if key, ok := accessCharKey(item.AccessChar); ok && inpututil.IsKeyJustPressed(key) {
+				return item.Name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+//accessCharKey maps an access character to the ebiten.Key that types it,
+//supporting the A-Z range used by AccessChar mnemonics
+func accessCharKey(r rune) (ebiten.Key, bool) {
+	r = unicode.ToUpper(r)
+	if r < 'A' || r > 'Z' {
+		return 0, false
+	}
+	return ebiten.KeyA + ebiten.Key(r-'A'), true
+}
+
+//drawAccessUnderline underlines the first glyph of item.Text matching
+//item.AccessChar, measuring with mplusNormalFont so the underline lines up
+//beneath the glyph it marks. Does nothing if AccessChar is unset or not found.
+func (m *MenuList) drawAccessUnderline(img *ebiten.Image, item MenuItem, colour *color.NRGBA) {
+	if item.AccessChar == 0 {
+		return
+	}
+
+	idx := -1
+	for i, r := range item.Text {
+		if unicode.ToLower(r) == unicode.ToLower(item.AccessChar) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return
+	}
+
+	r, _ := utf8.DecodeRuneInString(item.Text[idx:])
+	prefixWidth := font.MeasureString(mplusNormalFont, item.Text[:idx]).Ceil()
+	charWidth := font.MeasureString(mplusNormalFont, string(r)).Ceil()
+
+	const underlineThickness = 2
+	underline, _ := ebiten.NewImage(charWidth, underlineThickness, ebiten.FilterNearest)
+	underline.Fill(colour)
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Translate(float64(item.TxtX+prefixWidth), float64(item.TxtY+2))
+	img.DrawImage(underline, opts)
+}
+
+//drawShortcutLabel renders the name of item.Shortcut right-aligned inside the
+//item, leaving m.ShortKeyInset pixels of margin on the right
+func (m *MenuList) drawShortcutLabel(img *ebiten.Image, item MenuItem, colour *color.NRGBA) {
+	label := item.Shortcut.String()
+	labelWidth := font.MeasureString(mplusNormalFont, label).Ceil()
+	x := m.Width - m.ShortKeyInset - labelWidth
+	text.Draw(img, label, mplusNormalFont, x, item.TxtY, colour)
+}
+
+//MenuStack tracks a chain of open menus from root to the currently active
+//submenu. IncrementSelected/DecrementSelected always act on the menu on top
+//of the stack; opening an item's SubMenu pushes it, Back pops back to its parent.
+type MenuStack struct {
+	menus []*MenuList
+}
+
+//NewMenuStack creates a MenuStack with root as the only, active menu
+func NewMenuStack(root *MenuList) *MenuStack {
+	return &MenuStack{menus: []*MenuList{root}}
+}
+
+//Top returns the currently active menu, the one on top of the stack
+func (s *MenuStack) Top() *MenuList {
+	return s.menus[len(s.menus)-1]
+}
+
+//Open pushes child onto the stack, making it the active menu
+func (s *MenuStack) Open(child *MenuList) {
+	s.menus = append(s.menus, child)
+}
+
+//Back pops the active menu, returning to its parent. Back on the root menu does nothing
+func (s *MenuStack) Back() {
+	if len(s.menus) > 1 {
+		s.menus = s.menus[:len(s.menus)-1]
+	}
+}
+
+//IncrementSelected moves the selection down in the top-of-stack menu
+func (s *MenuStack) IncrementSelected() {
+	s.Top().IncrementSelected()
+}
+
+//DecrementSelected moves the selection up in the top-of-stack menu
+func (s *MenuStack) DecrementSelected() {
+	s.Top().DecrementSelected()
+}
+
+//Activate acts on the selected item of the top-of-stack menu: if it has a
+//SubMenu, Activate opens it and returns ("", false), otherwise it returns the
+//item's name and true for the caller to act on
+func (s *MenuStack) Activate() (string, bool) {
+	top := s.Top()
+	if !top.selectable(*top.SelectedIndex) {
+		return "", false
+	}
+
+	selected := top.MenuItems[*top.SelectedIndex]
+	if selected.SubMenu != nil {
+		s.Open(selected.SubMenu)
+		return "", false
+	}
+	return selected.Name, true
+}
+
+//Draw renders every menu on the stack, dimming all but the top (active) one
+func (s *MenuStack) Draw(screen *ebiten.Image) {
+	for i, m := range s.menus {
+		if i < len(s.menus)-1 {
+			m.drawDimmed(screen)
+		} else {
+			m.Draw(screen)
+		}
+	}
+}